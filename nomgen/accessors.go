@@ -0,0 +1,79 @@
+package nomgen
+
+import "strings"
+
+var (
+	accessorGetTempl   = readTemplate("accessor_get.tmpl")
+	accessorGetOkTempl = readTemplate("accessor_getok.tmpl")
+	accessorSetTempl   = readTemplate("accessor_set.tmpl")
+)
+
+type accessorData struct {
+	StructName  string
+	GoFieldName string
+	FieldName   string
+	FieldType   string
+}
+
+// writeAccessors emits the GetX/GetXOk/SetX trio for a single struct
+// field: GetX and GetXOk check Has on the backing map and return the
+// zero value (or ok=false) instead of panicking when the field is
+// absent, which the raw fieldTempl accessor does on schema evolution.
+// SetX returns a new wrapper with the field updated, preserving the
+// immutable-value style the rest of the generated code uses. Each is
+// skipped if config.Blacklist names it as "StructName.MethodName", so
+// a hand-written override in the same package isn't shadowed.
+func (ng *NG) writeAccessors(structName, goFieldName, fieldName, fieldType string) {
+	data := accessorData{
+		StructName:  structName,
+		GoFieldName: goFieldName,
+		FieldName:   fieldName,
+		FieldType:   fieldType,
+	}
+
+	if !ng.blacklisted(structName, "Get"+goFieldName) {
+		accessorGetTempl.Execute(ng.w, data)
+	}
+	if !ng.blacklisted(structName, "Get"+goFieldName+"Ok") {
+		accessorGetOkTempl.Execute(ng.w, data)
+	}
+	if !ng.blacklisted(structName, "Set"+goFieldName) {
+		accessorSetTempl.Execute(ng.w, data)
+	}
+}
+
+func (ng *NG) blacklisted(structName, methodName string) bool {
+	if ng.config == nil {
+		return false
+	}
+	key := structName + "." + methodName
+	for _, entry := range ng.config.Blacklist {
+		if entry == key {
+			return true
+		}
+	}
+	return false
+}
+
+// zeroNomsValue returns the Go expression for the zero value of a
+// generated field type: types.NewXxx(zero) for a primitive, or an
+// empty struct literal for a composite wrapper.
+func zeroNomsValue(fieldType string) string {
+	if !strings.HasPrefix(fieldType, "types.") {
+		return fieldType + "{}"
+	}
+	switch fieldType {
+	case "types.Value":
+		return "nil"
+	case "types.Set":
+		return "types.NewSet()"
+	case "types.Map":
+		return "types.NewMap()"
+	case "types.Bool":
+		return "types.NewBool(false)"
+	case "types.String":
+		return `types.NewString("")`
+	default:
+		return "types.New" + strings.TrimPrefix(fieldType, "types.") + "(0)"
+	}
+}
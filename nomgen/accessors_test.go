@@ -0,0 +1,42 @@
+package nomgen
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestAccessorsConvertCompositeFields is a regression test for the
+// original request: GetX/GetXOk on a composite field must convert the
+// stored raw value through fromVal rather than asserting it directly
+// to the wrapper type, which panics for every present field (not just
+// the missing-field case the request was actually about).
+func TestAccessorsConvertCompositeFields(t *testing.T) {
+	assert := assert.New(t)
+
+	ng := New(&bytes.Buffer{})
+	var buf bytes.Buffer
+	ng.w = &buf
+	ng.writeAccessors("Account", "Owner", "owner", "User")
+	out := buf.String()
+
+	assert.Contains(out, "return UserFromVal(v)")
+	assert.NotContains(out, "return v.(User)")
+}
+
+// TestAccessorsBlacklist covers the blacklist half of the same
+// request: a Blacklist entry should suppress only the named method.
+func TestAccessorsBlacklist(t *testing.T) {
+	assert := assert.New(t)
+
+	ng := New(&bytes.Buffer{})
+	ng.config = &Config{Blacklist: []string{"Account.SetOwner"}}
+	var buf bytes.Buffer
+	ng.w = &buf
+	ng.writeAccessors("Account", "Owner", "owner", "User")
+	out := buf.String()
+
+	assert.Contains(out, "func (s Account) GetOwner() User")
+	assert.NotContains(out, "func (s Account) SetOwner(")
+}
@@ -0,0 +1,143 @@
+package nomgen
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+
+	. "github.com/attic-labs/noms/dbg"
+	"github.com/attic-labs/noms/types"
+	"gopkg.in/yaml.v2"
+)
+
+// Config drives nomgen the way gqlgen's config.yaml drives schema
+// generation: it names the input schema, the output file/package, any
+// extra imports the generated file needs, and a set of noms-type-name
+// -> existing-Go-type bindings (Models) so generation can be re-run
+// idempotently via `go generate` alongside hand-written code.
+type Config struct {
+	// Schema is a noms typedef document to load, ignored when Source is set.
+	Schema string `yaml:"schema"`
+	// Source, when set, derives the schema from Go struct declarations
+	// via WriteGoFromSource instead of a typedef document.
+	Source *SourceConfig `yaml:"source,omitempty"`
+
+	Package string   `yaml:"package"`
+	Out     string   `yaml:"out"`
+	Imports []string `yaml:"imports"`
+
+	// Models binds the $name of a noms StructDef, NamedDef or UnionDef
+	// to an existing Go type (e.g. `Email: mypkg.Email`), which
+	// getGoTypeName consults before falling back to the generated
+	// wrapper name.
+	Models map[string]string `yaml:"models"`
+
+	// Skip lists glob patterns matched against generated type names;
+	// matches are not (re-)emitted, so hand-written code can coexist.
+	Skip []string `yaml:"skip"`
+
+	// CollectionNaming selects how ListDef/SetDef wrappers are named:
+	// "FooList" (the default) or "ListOfFoo".
+	CollectionNaming string `yaml:"collectionNaming"`
+
+	// JSON switches on MarshalJSON/UnmarshalJSON generation, same as NG.WithJSON.
+	JSON bool `yaml:"json"`
+
+	// Datasets binds a generated Set wrapper's Go type name (e.g.
+	// "UserSet") to the noms dataset name it's persisted under,
+	// turning on GetX/InsertX/RemoveX/CommitX facade generation for it.
+	Datasets map[string]string `yaml:"datasets"`
+
+	// Blacklist holds "StructName.MethodName" entries, the same
+	// convention go-github's gen-accessors.go uses, so a hand-written
+	// override in the same package isn't shadowed by a generated
+	// GetX/GetXOk/SetX accessor.
+	Blacklist []string `yaml:"blacklist"`
+}
+
+// SourceConfig points WriteGoFromSource at the Go package to derive
+// typedefs from.
+type SourceConfig struct {
+	Dir   string   `yaml:"dir"`
+	Types []string `yaml:"types"`
+}
+
+// LoadConfig reads and parses a nomgen config file.
+func LoadConfig(path string) (*Config, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Config{CollectionNaming: "FooList"}
+	if err := yaml.Unmarshal(b, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Generate runs nomgen end to end for c: it loads the schema named by
+// c.Schema (or derives one from c.Source), writes the result to
+// c.Out, and consults c.Models/c.Skip/c.CollectionNaming/c.JSON while
+// doing so.
+func (c *Config) Generate() error {
+	f, err := os.Create(c.Out)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	ng := New(f)
+	ng.config = c
+	ng.WithJSON(c.JSON)
+
+	if c.Source != nil {
+		return ng.WriteGoFromSource(c.Source.Dir, c.Source.Types)
+	}
+
+	val, err := loadTypedef(c.Schema)
+	if err != nil {
+		return err
+	}
+	ng.WriteGo(val, c.Package)
+	return nil
+}
+
+// loadTypedef reads a noms typedef document -- the same $type-tagged
+// nested-map shape WriteGo already expects -- from a JSON file on disk.
+func loadTypedef(path string) (types.Map, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return types.Map{}, err
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return types.Map{}, err
+	}
+	return jsonToTypedef(raw).(types.Map), nil
+}
+
+func jsonToTypedef(raw interface{}) types.Value {
+	switch raw := raw.(type) {
+	case string:
+		return types.NewString(raw)
+	case map[string]interface{}:
+		kv := []types.Value{}
+		for k, v := range raw {
+			kv = append(kv, types.NewString(k), jsonToTypedef(v))
+		}
+		return types.NewMap(kv...)
+	case []interface{}:
+		// A noms.UnionDef's "choices" is the only array-valued key a
+		// schema document needs, but any typedef nested under one goes
+		// through the same recursive conversion as a map's values.
+		vals := make([]types.Value, len(raw))
+		for i, v := range raw {
+			vals[i] = jsonToTypedef(v)
+		}
+		return types.NewList(vals...)
+	}
+	Chk.Fail("nomgen: unexpected value in schema document: %+v", raw)
+	return nil
+}
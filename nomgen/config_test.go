@@ -0,0 +1,48 @@
+package nomgen
+
+import (
+	"testing"
+
+	"github.com/attic-labs/noms/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestJSONToTypedefUnionChoices is a regression test for the original
+// request: a schema document driving Config.Schema is the only place
+// a noms.UnionDef's "choices" array is loaded from JSON, so
+// jsonToTypedef needs to turn a JSON array into a types.List rather
+// than panicking on the unexpected-value case.
+func TestJSONToTypedefUnionChoices(t *testing.T) {
+	assert := assert.New(t)
+
+	raw := map[string]interface{}{
+		"$type":   "noms.UnionDef",
+		"$name":   "IntOrString",
+		"choices": []interface{}{"int64", "string"},
+	}
+
+	def := jsonToTypedef(raw).(types.Map)
+	choices := def.Get(types.NewString("choices")).(types.List)
+	assert.EqualValues(2, choices.Len())
+}
+
+// TestModelForNamedDef is a regression test for the original request:
+// a models: binding should apply to a NamedDef (and UnionDef) typedef,
+// not just StructDef, since the request's own example (Email) is a
+// NamedDef once chunk0-5 landed.
+func TestModelForNamedDef(t *testing.T) {
+	assert := assert.New(t)
+
+	ng := New(nil)
+	ng.config = &Config{Models: map[string]string{"Email": "mypkg.Email"}}
+
+	namedDef := types.NewMap(
+		types.NewString("$type"), types.NewString("noms.NamedDef"),
+		types.NewString("$name"), types.NewString("Email"),
+		types.NewString("alias"), types.NewString("string"),
+	)
+
+	model, ok := ng.modelFor(namedDef)
+	assert.True(ok)
+	assert.Equal("mypkg.Email", model)
+}
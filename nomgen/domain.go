@@ -0,0 +1,103 @@
+package nomgen
+
+import (
+	. "github.com/attic-labs/noms/dbg"
+	"github.com/attic-labs/noms/types"
+)
+
+// writeNamed handles noms.NamedDef, which wraps an existing typedef
+// under a new Go type name so domain types like Email or UserID are
+// distinguishable from the bare types.String (or collection) they're
+// built on -- the alias/rule-wrapping idea kego's system.WrapRule uses
+// for InnerType.
+func (ng *NG) writeNamed(val types.Map) {
+	name := val.Get(types.NewString("$name")).(types.String).String()
+	alias := val.Get(types.NewString("alias"))
+	ng.addType(alias)
+
+	data := struct {
+		StructName string
+		AliasName  string
+	}{
+		name,
+		ng.getGoTypeName(ng.resolveAlias(alias)),
+	}
+
+	namedTempl.Execute(ng.w, data)
+	if ng.withJSON {
+		jsonNamedTempl.Execute(ng.w, data)
+	}
+}
+
+// resolveAlias follows a chain of NamedDef wrappers down to the first
+// typedef that isn't one, the way kego walks `for uit.Alias != nil { uit = uit.Alias }`
+// to find a rule's ultimate inner type.
+func (ng *NG) resolveAlias(typeDef types.Value) types.Value {
+	for {
+		m, ok := typeDef.(types.Map)
+		if !ok || ng.getTypeTag(m) != "noms.NamedDef" {
+			return typeDef
+		}
+		typeDef = m.Get(types.NewString("alias"))
+	}
+}
+
+type unionAltData struct {
+	TypeName string
+	// Tag is the noms runtime type name the alternative serializes as
+	// (e.g. a StructDef/NamedDef/UnionDef's $name, or a primitive's
+	// noms kind name like "string") -- what v.Type().Name() returns at
+	// runtime, not the Go wrapper type, since the union's FromVal only
+	// ever sees the raw noms value, never the wrapper.
+	Tag string
+}
+
+// writeUnion handles noms.UnionDef, a list of alternative typedefs
+// that becomes a single Go wrapper type plus one FromVal implementation
+// with a switch over the alternatives' noms runtime type tags -- the
+// wrapper carries the matched value and exposes NomsValue() like every
+// other generated type, so it plugs into the existing fromVal/toVal
+// conventions for free.
+func (ng *NG) writeUnion(val types.Map) {
+	name := val.Get(types.NewString("$name")).(types.String).String()
+	choices := val.Get(types.NewString("choices")).(types.List)
+
+	alts := []unionAltData{}
+	choices.Iter(func(v types.Value, idx uint64) (stop bool) {
+		ng.addType(v)
+		alts = append(alts, unionAltData{TypeName: ng.getGoTypeName(v), Tag: ng.unionTag(v)})
+		return
+	})
+
+	data := struct {
+		StructName string
+		Alts       []unionAltData
+	}{
+		name,
+		alts,
+	}
+
+	unionTempl.Execute(ng.w, data)
+	if ng.withJSON {
+		jsonUnionTempl.Execute(ng.w, data)
+	}
+}
+
+// unionTag returns the noms runtime type name a typedef's values carry
+// at runtime: a StructDef/NamedDef/UnionDef's $name, or a primitive's
+// noms kind name (e.g. "string"). This is what a union's generated
+// FromVal switches on, since the value it receives is always the raw
+// noms representation, never a generated Go wrapper.
+func (ng *NG) unionTag(typeDef types.Value) string {
+	switch typeDef := typeDef.(type) {
+	case types.String:
+		return typeDef.String()
+	case types.Map:
+		switch ng.getTypeTag(typeDef) {
+		case "noms.StructDef", "noms.NamedDef", "noms.UnionDef":
+			return typeDef.Get(types.NewString("$name")).(types.String).String()
+		}
+	}
+	Chk.Fail("nomgen: union alternative has no runtime type tag: %+v", typeDef)
+	return ""
+}
@@ -0,0 +1,56 @@
+package nomgen
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/attic-labs/noms/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestUnionJSON is a regression test for the original request: a
+// UnionDef generated with JSON on needs its own MarshalJSON/
+// UnmarshalJSON, since the wrapper doesn't satisfy either via
+// reflection (its one field, v, is unexported).
+func TestUnionJSON(t *testing.T) {
+	assert := assert.New(t)
+
+	unionDef := types.NewMap(
+		types.NewString("$type"), types.NewString("noms.UnionDef"),
+		types.NewString("$name"), types.NewString("IntOrString"),
+		types.NewString("choices"), types.NewList(types.NewString("int64"), types.NewString("string")),
+	)
+
+	ng := New(&bytes.Buffer{})
+	ng.withJSON = true
+	var buf bytes.Buffer
+	ng.w = &buf
+	ng.writeUnion(unionDef)
+	out := buf.String()
+
+	assert.Contains(out, "func (s IntOrString) MarshalJSON() ([]byte, error)")
+	assert.Contains(out, "func (s *IntOrString) UnmarshalJSON(data []byte) error")
+}
+
+// TestNamedJSON covers the NamedDef half of the same request: Email
+// (a NamedDef over string) generated with JSON on should marshal
+// through its alias rather than silently falling back to reflection.
+func TestNamedJSON(t *testing.T) {
+	assert := assert.New(t)
+
+	namedDef := types.NewMap(
+		types.NewString("$type"), types.NewString("noms.NamedDef"),
+		types.NewString("$name"), types.NewString("Email"),
+		types.NewString("alias"), types.NewString("string"),
+	)
+
+	ng := New(&bytes.Buffer{})
+	ng.withJSON = true
+	var buf bytes.Buffer
+	ng.w = &buf
+	ng.writeNamed(namedDef)
+	out := buf.String()
+
+	assert.Contains(out, "func (s Email) MarshalJSON() ([]byte, error)")
+	assert.Contains(out, "func (s *Email) UnmarshalJSON(data []byte) error")
+}
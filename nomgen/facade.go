@@ -0,0 +1,58 @@
+package nomgen
+
+import "github.com/attic-labs/noms/types"
+
+// facadeFieldData describes one element-struct field for facade.tmpl,
+// which needs both the noms field name (map key, param name) and the
+// Go type of the corresponding InsertX parameter.
+type facadeFieldData struct {
+	FieldName string
+	FieldType string
+}
+
+type facadeData struct {
+	SetName    string
+	ElemName   string
+	PluralName string
+	Dataset    string
+	Fields     []facadeFieldData
+}
+
+// maybeWriteFacade emits the Get/Insert/Remove/Commit functions for a
+// top-level SetDef(Struct) pair when config.Datasets names a dataset
+// for it -- the same facade the user package used to hand-write around
+// datastore.DataStore before nomgen could generate it.
+func (ng *NG) maybeWriteFacade(setVal types.Map, elem types.Value) {
+	if ng.config == nil || len(ng.config.Datasets) == 0 {
+		return
+	}
+
+	elemMap, ok := elem.(types.Map)
+	if !ok || ng.getTypeTag(elemMap) != "noms.StructDef" {
+		return
+	}
+
+	setName := ng.getGoTypeName(setVal)
+	dataset, ok := ng.config.Datasets[setName]
+	if !ok {
+		return
+	}
+
+	elemName := ng.getGoTypeName(elemMap)
+	fields := []facadeFieldData{}
+	elemMap.Iter(func(k, v types.Value) (stop bool) {
+		sk := k.(types.String).String()
+		if sk[0] != '$' {
+			fields = append(fields, facadeFieldData{FieldName: sk, FieldType: ng.getGoTypeName(v)})
+		}
+		return
+	})
+
+	facadeTempl.Execute(ng.w, facadeData{
+		SetName:    setName,
+		ElemName:   elemName,
+		PluralName: elemName + "s",
+		Dataset:    dataset,
+		Fields:     fields,
+	})
+}
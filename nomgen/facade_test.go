@@ -0,0 +1,41 @@
+package nomgen
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/attic-labs/noms/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestFacadeInsertPrimitiveField is a regression test for the original
+// request: InsertX's generated body must not run an already-wrapped
+// field value back through its types.NewX constructor, since that
+// fails to compile (types.NewString wants a raw string, not a
+// types.String) for the exact User/email shape the request is built
+// around.
+func TestFacadeInsertPrimitiveField(t *testing.T) {
+	assert := assert.New(t)
+
+	elem := types.NewMap(
+		types.NewString("$type"), types.NewString("noms.StructDef"),
+		types.NewString("$name"), types.NewString("User"),
+		types.NewString("email"), types.NewString("string"),
+	)
+	setDef := types.NewMap(
+		types.NewString("$type"), types.NewString("noms.SetDef"),
+		types.NewString("elem"), elem,
+	)
+
+	ng := New(&bytes.Buffer{})
+	ng.config = &Config{Datasets: map[string]string{"UserSet": "users"}}
+
+	var buf bytes.Buffer
+	ng.w = &buf
+	ng.maybeWriteFacade(setDef, elem)
+	out := buf.String()
+
+	assert.Contains(out, "func InsertUser(s UserSet, email types.String) UserSet")
+	assert.Contains(out, `types.NewString("email"), email,`)
+	assert.NotContains(out, "types.NewString(email)")
+}
@@ -15,26 +15,69 @@ import (
 )
 
 var (
-	fieldTempl  = readTemplate("field.tmpl")
-	headerTmpl  = readTemplate("header.tmpl")
-	listTempl   = readTemplate("list.tmpl")
-	mapTempl    = readTemplate("map.tmpl")
-	setTempl    = readTemplate("set.tmpl")
-	structTempl = readTemplate("struct.tmpl")
+	fieldTempl      = readTemplate("field.tmpl")
+	headerTmpl      = readTemplate("header.tmpl")
+	listTempl       = readTemplate("list.tmpl")
+	mapTempl        = readTemplate("map.tmpl")
+	setTempl        = readTemplate("set.tmpl")
+	structTempl     = readTemplate("struct.tmpl")
+	jsonStructTempl = readTemplate("json_struct.tmpl")
+	jsonListTempl   = readTemplate("json_list.tmpl")
+	jsonMapTempl    = readTemplate("json_map.tmpl")
+	jsonSetTempl    = readTemplate("json_set.tmpl")
+	facadeTempl     = readTemplate("facade.tmpl")
+	namedTempl      = readTemplate("named.tmpl")
+	unionTempl      = readTemplate("union.tmpl")
+	jsonNamedTempl  = readTemplate("json_named.tmpl")
+	jsonUnionTempl  = readTemplate("json_union.tmpl")
 )
 
 type NG struct {
-	w       io.Writer
-	written types.Set
-	toWrite types.Set
+	w        io.Writer
+	written  types.Set
+	toWrite  types.Set
+	withJSON bool
+	config   *Config
 }
 
-func New(w io.Writer) NG {
-	return NG{w: w, written: types.NewSet(), toWrite: types.NewSet()}
+func New(w io.Writer) *NG {
+	return &NG{w: w, written: types.NewSet(), toWrite: types.NewSet()}
+}
+
+// WithJSON switches on generation of MarshalJSON/UnmarshalJSON methods
+// alongside the usual noms-backed wrappers, for types that need to be
+// sent over the wire as ordinary JSON (e.g. HTTP payloads between noms
+// clients). It returns ng so it can be chained off New.
+func (ng *NG) WithJSON(on bool) *NG {
+	ng.withJSON = on
+	return ng
+}
+
+// headerData is the data passed to header.tmpl: the output package
+// name plus any extra imports the generated file needs (e.g. from a
+// Config's imports: list).
+type headerData struct {
+	PackageName string
+	Imports     []string
+}
+
+// imports returns the extra import paths the generated file needs: any
+// Config.Imports the caller asked for, plus encoding/json and fmt when
+// WithJSON is on (the json_*.tmpl bodies use both, and WithJSON works
+// with no Config at all, so this can't just be left to the config).
+func (ng *NG) imports() []string {
+	var imports []string
+	if ng.config != nil {
+		imports = append(imports, ng.config.Imports...)
+	}
+	if ng.withJSON {
+		imports = append(imports, "encoding/json", "fmt")
+	}
+	return imports
 }
 
 func (ng *NG) WriteGo(val types.Map, pkg string) {
-	headerTmpl.Execute(ng.w, struct{ PackageName string }{pkg})
+	headerTmpl.Execute(ng.w, headerData{PackageName: pkg, Imports: ng.imports()})
 
 	ng.addType(val)
 
@@ -75,6 +118,16 @@ func toNomsValue(name string) string {
 	return ".NomsValue()"
 }
 
+// newNomsValue returns the types.NewXxx constructor for a primitive
+// field type (e.g. "types.Bool" -> "types.NewBool"), or "" for a
+// composite field whose wrapper already exposes NomsValue().
+func newNomsValue(name string) string {
+	if !strings.HasPrefix(name, "types.") {
+		return ""
+	}
+	return "types.New" + strings.TrimPrefix(name, "types.")
+}
+
 func readTemplate(name string) *template.Template {
 	_, thisfile, _, _ := runtime.Caller(1)
 	f, err := os.Open(path.Join(path.Dir(thisfile), name))
@@ -85,13 +138,19 @@ func readTemplate(name string) *template.Template {
 	t, err := template.New(name).Funcs(template.FuncMap{
 		"fromVal": fromNomsValue,
 		"toVal":   toNomsValue,
+		"newVal":  newNomsValue,
+		"isPrim":  func(name string) bool { return strings.HasPrefix(name, "types.") },
+		"zeroVal": zeroNomsValue,
 	}).Parse(string(b))
 	Chk.NoError(err)
 	return t
 }
 
 func (ng *NG) writeType(val types.Map) {
-	typ := val.Get(types.NewString("$type")).(types.String).String()
+	typ := ng.getTypeTag(val)
+	if ng.shouldSkip(ng.getGoStructName(val)) {
+		return
+	}
 	switch typ {
 	case "noms.ListDef":
 		ng.writeList(val)
@@ -105,6 +164,12 @@ func (ng *NG) writeType(val types.Map) {
 	case "noms.StructDef":
 		ng.writeStruct(val)
 		return
+	case "noms.NamedDef":
+		ng.writeNamed(val)
+		return
+	case "noms.UnionDef":
+		ng.writeUnion(val)
+		return
 	}
 	Chk.Fail(fmt.Sprintf("Unexpected typedef: %+v", val))
 }
@@ -117,11 +182,15 @@ func (ng *NG) writeSet(val types.Map) {
 		StructName string
 		ElemName   string
 	}{
-		getGoTypeName(val),
-		getGoTypeName(elem),
+		ng.getGoTypeName(val),
+		ng.getGoTypeName(elem),
 	}
 
 	setTempl.Execute(ng.w, data)
+	if ng.withJSON {
+		jsonSetTempl.Execute(ng.w, data)
+	}
+	ng.maybeWriteFacade(val, elem)
 }
 
 func (ng *NG) writeList(val types.Map) {
@@ -132,11 +201,14 @@ func (ng *NG) writeList(val types.Map) {
 		StructName string
 		ElemName   string
 	}{
-		getGoTypeName(val),
-		getGoTypeName(elem),
+		ng.getGoTypeName(val),
+		ng.getGoTypeName(elem),
 	}
 
 	listTempl.Execute(ng.w, data)
+	if ng.withJSON {
+		jsonListTempl.Execute(ng.w, data)
+	}
 }
 
 func (ng *NG) writeMap(val types.Map) {
@@ -150,32 +222,55 @@ func (ng *NG) writeMap(val types.Map) {
 		KeyName    string
 		ValueName  string
 	}{
-		getGoTypeName(val),
-		getGoTypeName(key),
-		getGoTypeName(valueName),
+		ng.getGoTypeName(val),
+		ng.getGoTypeName(key),
+		ng.getGoTypeName(valueName),
 	}
 
 	mapTempl.Execute(ng.w, data)
+	if ng.withJSON {
+		jsonMapTempl.Execute(ng.w, data)
+	}
 }
 
 func (ng *NG) writeStruct(val types.Map) {
-	structName := getGoTypeName(val)
+	structName := ng.getGoTypeName(val)
 	structTempl.Execute(ng.w, struct {
 		StructName string
 	}{
-		getGoTypeName(val),
+		ng.getGoTypeName(val),
 	})
 
+	fields := []jsonFieldData{}
 	val.Iter(func(k, v types.Value) (stop bool) {
 		sk := k.(types.String).String()
 		if sk[0] != '$' {
-			ng.writeField(structName, sk, v)
+			fields = append(fields, ng.writeField(structName, sk, v))
 		}
 		return
 	})
+
+	if ng.withJSON {
+		jsonStructTempl.Execute(ng.w, struct {
+			StructName string
+			Fields     []jsonFieldData
+		}{
+			structName,
+			fields,
+		})
+	}
 }
 
-func (ng *NG) writeField(structName, fieldName string, typeDef types.Value) {
+// jsonFieldData carries the same per-field naming fieldTempl uses, so
+// jsonStructTempl can key its Marshal/UnmarshalJSON cases off the noms
+// field name while still calling the generated Go accessors.
+type jsonFieldData struct {
+	FieldType   string
+	GoFieldName string
+	FieldName   string
+}
+
+func (ng *NG) writeField(structName, fieldName string, typeDef types.Value) jsonFieldData {
 	ng.addType(typeDef)
 
 	data := struct {
@@ -185,16 +280,27 @@ func (ng *NG) writeField(structName, fieldName string, typeDef types.Value) {
 		FieldName   string
 	}{
 		structName,
-		getGoTypeName(typeDef),
+		ng.getGoTypeName(typeDef),
 		strings.Title(fieldName),
 		fieldName,
 	}
 
 	fieldTempl.Execute(ng.w, data)
+	ng.writeAccessors(structName, data.GoFieldName, data.FieldName, data.FieldType)
+
+	return jsonFieldData{
+		FieldType:   data.FieldType,
+		GoFieldName: data.GoFieldName,
+		FieldName:   data.FieldName,
+	}
 }
 
-func getGoTypeName(typeDef types.Value) string {
-	typeName := getGoStructName(typeDef)
+func (ng *NG) getGoTypeName(typeDef types.Value) string {
+	if model, ok := ng.modelFor(typeDef); ok {
+		return model
+	}
+
+	typeName := ng.getGoStructName(typeDef)
 	switch typeDef.(type) {
 	case types.String:
 		return fmt.Sprintf("types.%s", typeName)
@@ -202,7 +308,32 @@ func getGoTypeName(typeDef types.Value) string {
 	return typeName
 }
 
-func getGoStructName(typeDef types.Value) string {
+// modelFor consults config.Models, the way gqlgen's models: map binds a
+// schema type name to an existing Go type, so hand-written types (e.g.
+// a validated Email) can stand in for a generated wrapper.
+func (ng *NG) modelFor(typeDef types.Value) (string, bool) {
+	if ng.config == nil || len(ng.config.Models) == 0 {
+		return "", false
+	}
+	m, ok := typeDef.(types.Map)
+	if !ok {
+		return "", false
+	}
+	switch ng.getTypeTag(m) {
+	case "noms.StructDef", "noms.NamedDef", "noms.UnionDef":
+	default:
+		return "", false
+	}
+	name := m.Get(types.NewString("$name")).(types.String).String()
+	model, ok := ng.config.Models[name]
+	return model, ok
+}
+
+func (ng *NG) getTypeTag(m types.Map) string {
+	return m.Get(types.NewString("$type")).(types.String).String()
+}
+
+func (ng *NG) getGoStructName(typeDef types.Value) string {
 	switch typeDef := typeDef.(type) {
 	case types.String:
 		name := typeDef.String()
@@ -212,17 +343,17 @@ func getGoStructName(typeDef types.Value) string {
 		}
 		Chk.Fail("unexpected noms type name: %s", name)
 	case types.Map:
-		typ := typeDef.Get(types.NewString("$type")).(types.String).String()
+		typ := ng.getTypeTag(typeDef)
 		switch typ {
 		case "noms.ListDef":
-			return fmt.Sprintf("%sList", getGoStructName(typeDef.Get(types.NewString("elem"))))
+			return fmt.Sprintf(ng.collectionFormat("List"), ng.getGoStructName(typeDef.Get(types.NewString("elem"))))
 		case "noms.MapDef":
 			return fmt.Sprintf("%s%sMap",
-				getGoStructName(typeDef.Get(types.NewString("key"))),
-				getGoStructName(typeDef.Get(types.NewString("value"))))
+				ng.getGoStructName(typeDef.Get(types.NewString("key"))),
+				ng.getGoStructName(typeDef.Get(types.NewString("value"))))
 		case "noms.SetDef":
-			return fmt.Sprintf("%sSet", getGoStructName(typeDef.Get(types.NewString("elem"))))
-		case "noms.StructDef":
+			return fmt.Sprintf(ng.collectionFormat("Set"), ng.getGoStructName(typeDef.Get(types.NewString("elem"))))
+		case "noms.StructDef", "noms.NamedDef", "noms.UnionDef":
 			return typeDef.Get(types.NewString("$name")).(types.String).String()
 		}
 	}
@@ -230,6 +361,31 @@ func getGoStructName(typeDef types.Value) string {
 	return ""
 }
 
+// collectionFormat returns the Printf format used to name a generated
+// List/Set wrapper, honoring config.CollectionNaming ("FooList", the
+// default, vs "ListOfFoo").
+func (ng *NG) collectionFormat(suffix string) string {
+	if ng.config != nil && ng.config.CollectionNaming == "ListOfFoo" {
+		return strings.Title(suffix) + "Of%s"
+	}
+	return "%s" + suffix
+}
+
+// shouldSkip reports whether name matches one of config.Skip's glob
+// patterns, meaning a hand-written type already exists and this
+// wrapper should not be (re-)emitted.
+func (ng *NG) shouldSkip(name string) bool {
+	if ng.config == nil {
+		return false
+	}
+	for _, pattern := range ng.config.Skip {
+		if ok, err := path.Match(pattern, name); ok && err == nil {
+			return true
+		}
+	}
+	return false
+}
+
 func (ng *NG) writeStr(str string, vals ...interface{}) {
 	io.WriteString(ng.w, fmt.Sprintf(str, vals...))
 }
\ No newline at end of file
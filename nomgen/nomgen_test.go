@@ -0,0 +1,34 @@
+package nomgen
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/attic-labs/noms/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestWriteGoJSON is a regression test for the original request: a
+// struct with a primitive field, generated with JSON on, should come
+// out with both the noms wrapper and Marshal/UnmarshalJSON methods,
+// and the Marshal body should call the field's own accessor rather
+// than unwrap it to a raw types.Value first.
+func TestWriteGoJSON(t *testing.T) {
+	assert := assert.New(t)
+
+	typeDef := types.NewMap(
+		types.NewString("$type"), types.NewString("noms.StructDef"),
+		types.NewString("$name"), types.NewString("Widget"),
+		types.NewString("name"), types.NewString("string"),
+	)
+
+	var buf bytes.Buffer
+	New(&buf).WithJSON(true).WriteGo(typeDef, "widget")
+	out := buf.String()
+
+	assert.Contains(out, "type Widget struct")
+	assert.Contains(out, "func WidgetFromVal(v types.Value) Widget")
+	assert.Contains(out, "func (s Widget) MarshalJSON() ([]byte, error)")
+	assert.Contains(out, `m["name"] = s.Name()`)
+	assert.NotContains(out, "s.Name().NomsValue()")
+}
@@ -0,0 +1,214 @@
+package nomgen
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strings"
+
+	. "github.com/attic-labs/noms/dbg"
+	"github.com/attic-labs/noms/types"
+)
+
+// goPrimitives maps the Go basic type names that have a direct noms
+// equivalent to the noms typedef name writeType/getGoStructName expect.
+var goPrimitives = map[string]string{
+	"bool":    "bool",
+	"int16":   "int16",
+	"int32":   "int32",
+	"int64":   "int64",
+	"uint16":  "uint16",
+	"uint32":  "uint32",
+	"uint64":  "uint64",
+	"float32": "float32",
+	"float64": "float64",
+	"string":  "string",
+}
+
+// sourceBuilder walks the AST of a target package and turns its struct
+// declarations into the same in-memory noms typedef (types.Map) shape
+// that WriteGo expects, so hand-built ListDef/MapDef/SetDef/StructDef
+// maps are no longer required to drive the generator.
+type sourceBuilder struct {
+	ng      *NG
+	structs map[string]*ast.StructType
+	built   map[string]types.Map
+}
+
+// WriteGoFromSource parses the Go package rooted at pkgDir and, for each
+// name in typeNames, derives a noms typedef from the corresponding
+// struct declaration before feeding it into the existing writeType
+// pipeline -- the same code path WriteGo uses for hand-built
+// types.Map typedefs. Nested struct fields become embedded
+// noms.StructDef references, []T becomes a ListDef, map[K]V becomes a
+// MapDef, and map[T]struct{} becomes a SetDef, all discovered
+// transitively the way addType already walks collection element types.
+//
+// A `noms:"name"` struct tag renames a field; `noms:"-"` skips it, the
+// same convention encoding/json uses.
+func (ng *NG) WriteGoFromSource(pkgDir string, typeNames []string) error {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, pkgDir, nil, 0)
+	if err != nil {
+		return err
+	}
+
+	var pkgName string
+	structs := map[string]*ast.StructType{}
+	for name, pkg := range pkgs {
+		if strings.HasSuffix(name, "_test") {
+			continue
+		}
+		pkgName = name
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				gd, ok := decl.(*ast.GenDecl)
+				if !ok || gd.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range gd.Specs {
+					ts, ok := spec.(*ast.TypeSpec)
+					if !ok {
+						continue
+					}
+					if st, ok := ts.Type.(*ast.StructType); ok {
+						structs[ts.Name.Name] = st
+					}
+				}
+			}
+		}
+	}
+
+	b := &sourceBuilder{ng: ng, structs: structs, built: map[string]types.Map{}}
+
+	headerTmpl.Execute(ng.w, headerData{PackageName: pkgName, Imports: ng.imports()})
+
+	for _, name := range typeNames {
+		ng.addType(b.structDef(name))
+	}
+
+	for !ng.toWrite.Empty() {
+		t := ng.toWrite.Any()
+		ng.toWrite = ng.toWrite.Remove(t)
+		ng.written = ng.written.Insert(t)
+		ng.writeType(t.(types.Map))
+	}
+
+	return nil
+}
+
+// structDef returns the noms.StructDef typedef for the named struct,
+// building and memoizing it (and its field typedefs) on first use so
+// that a struct referenced from more than one place is only built once.
+func (b *sourceBuilder) structDef(name string) types.Map {
+	if def, ok := b.built[name]; ok {
+		return def
+	}
+
+	st, ok := b.structs[name]
+	if !ok {
+		Chk.Fail("no struct declaration found for %s", name)
+	}
+
+	// Reserve the name before recursing, so a struct that refers back to
+	// itself (directly or through a collection) terminates instead of
+	// looping forever. The reservation is a bare $type/$name reference
+	// rather than the real (field-less) def under construction: it's
+	// pre-registered with ng as already written, so any addType call
+	// the generator later makes on it (e.g. from writeList/writeSet for
+	// an "elem" typedef) is a no-op, and the complete def built below is
+	// the only definition of name that ever actually gets queued and
+	// emitted.
+	bare := types.NewMap(
+		types.NewString("$type"), types.NewString("noms.StructDef"),
+		types.NewString("$name"), types.NewString(name),
+	)
+	b.ng.written = b.ng.written.Insert(bare)
+	b.built[name] = bare
+
+	fields := []types.Value{
+		types.NewString("$type"), types.NewString("noms.StructDef"),
+		types.NewString("$name"), types.NewString(name),
+	}
+	for _, f := range st.Fields.List {
+		fieldName, skip := b.fieldName(f)
+		if skip {
+			continue
+		}
+		typeDef := b.typeDefFor(f.Type)
+		fields = append(fields, types.NewString(fieldName), typeDef)
+	}
+
+	def := types.NewMap(fields...)
+	b.built[name] = def
+	return def
+}
+
+// fieldName applies the `noms:"name,omitempty"` / `noms:"-"` struct tag
+// convention, falling back to the exported Go field name.
+func (b *sourceBuilder) fieldName(f *ast.Field) (name string, skip bool) {
+	if len(f.Names) != 1 {
+		Chk.Fail("nomgen: embedded or multi-name fields are not supported")
+	}
+	name = f.Names[0].Name
+
+	if f.Tag == nil {
+		return name, false
+	}
+	tag := reflect.StructTag(strings.Trim(f.Tag.Value, "`")).Get("noms")
+	if tag == "" {
+		return name, false
+	}
+	parts := strings.Split(tag, ",")
+	if parts[0] == "-" {
+		return "", true
+	}
+	if parts[0] != "" {
+		name = parts[0]
+	}
+	return name, false
+}
+
+// typeDefFor converts a field's Go type expression into the noms
+// typedef types.Value WriteGo's addType/writeType pipeline expects:
+// a types.String for primitives, or a types.Map carrying one of the
+// $type tags (noms.ListDef/MapDef/SetDef/StructDef) for everything
+// else.
+func (b *sourceBuilder) typeDefFor(expr ast.Expr) types.Value {
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		if noms, ok := goPrimitives[expr.Name]; ok {
+			return types.NewString(noms)
+		}
+		if _, ok := b.structs[expr.Name]; ok {
+			return b.structDef(expr.Name)
+		}
+		Chk.Fail("nomgen: unsupported field type %s", expr.Name)
+	case *ast.StarExpr:
+		return b.typeDefFor(expr.X)
+	case *ast.ArrayType:
+		return types.NewMap(
+			types.NewString("$type"), types.NewString("noms.ListDef"),
+			types.NewString("elem"), b.typeDefFor(expr.Elt))
+	case *ast.MapType:
+		if isEmptyStruct(expr.Value) {
+			return types.NewMap(
+				types.NewString("$type"), types.NewString("noms.SetDef"),
+				types.NewString("elem"), b.typeDefFor(expr.Key))
+		}
+		return types.NewMap(
+			types.NewString("$type"), types.NewString("noms.MapDef"),
+			types.NewString("key"), b.typeDefFor(expr.Key),
+			types.NewString("value"), b.typeDefFor(expr.Value))
+	}
+	Chk.Fail("nomgen: unsupported field type %+v", expr)
+	return nil
+}
+
+// isEmptyStruct reports whether expr is the type struct{}, used to spot
+// the idiomatic Go set-as-map-to-empty-struct pattern.
+func isEmptyStruct(expr ast.Expr) bool {
+	st, ok := expr.(*ast.StructType)
+	return ok && st.Fields.NumFields() == 0
+}
@@ -0,0 +1,52 @@
+package nomgen
+
+import (
+	"bytes"
+	"go/ast"
+	"strings"
+	"testing"
+
+	"github.com/attic-labs/noms/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSelfReferentialStruct is a regression test for the original
+// request: a struct that refers back to itself through a collection
+// (Node.Children []Node) should be written out exactly once, not as a
+// complete definition plus a stray field-less duplicate.
+func TestSelfReferentialStruct(t *testing.T) {
+	assert := assert.New(t)
+
+	node := &ast.StructType{
+		Fields: &ast.FieldList{
+			List: []*ast.Field{
+				{
+					Names: []*ast.Ident{ast.NewIdent("Children")},
+					Type:  &ast.ArrayType{Elt: ast.NewIdent("Node")},
+				},
+			},
+		},
+	}
+
+	ng := New(&bytes.Buffer{})
+	b := &sourceBuilder{ng: ng, structs: map[string]*ast.StructType{"Node": node}, built: map[string]types.Map{}}
+
+	def := b.structDef("Node")
+
+	assert.True(def.Has(types.NewString("Children")))
+	elem := def.Get(types.NewString("Children")).(types.Map).Get(types.NewString("elem")).(types.Map)
+	assert.EqualValues("Node", elem.Get(types.NewString("$name")).(types.String).String())
+
+	var buf bytes.Buffer
+	ng.w = &buf
+	ng.addType(def)
+	for !ng.toWrite.Empty() {
+		v := ng.toWrite.Any()
+		ng.toWrite = ng.toWrite.Remove(v)
+		ng.written = ng.written.Insert(v)
+		ng.writeType(v.(types.Map))
+	}
+
+	out := buf.String()
+	assert.Equal(1, strings.Count(out, "type Node struct"))
+}